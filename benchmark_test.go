@@ -1,6 +1,7 @@
 package router
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -29,3 +30,21 @@ func BenchmarkOneRoute(b *testing.B) {
 		rt.ServeHTTP(testReq, req)
 	}
 }
+
+func BenchmarkUnregisteredMethod(b *testing.B) {
+	rt := NewRouter(&RouterOption{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for i := 0; i < 50; i++ {
+		rt.GET(fmt.Sprintf("/static%d/", i), handler)
+		rt.GET(fmt.Sprintf("/param%d/:id/", i), handler)
+		rt.DELEGATE(fmt.Sprintf("/files%d/", i), MethodGet, handler)
+	}
+	for _, path := range []string{"/param0/12/", "/missing/12/"} {
+		b.Run(path, func(b *testing.B) {
+			req, _ := http.NewRequest("BREW", path, nil)
+			for i := 0; i < b.N; i++ {
+				rt.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		})
+	}
+}
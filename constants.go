@@ -2,6 +2,7 @@ package router
 
 import (
 	"errors"
+	"net/http"
 	"regexp"
 )
 
@@ -22,6 +23,13 @@ var validatePathRegex, _ = regexp.Compile(`\/((.?)*\/)*`)
 
 var hasSpaceRegex, _ = regexp.Compile(`\s`)
 
+// namedConstraints are the built-in constraints usable inline as :param<name>.
+var namedConstraints = map[string]*regexp.Regexp{
+	"uuid": regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+	"int":  regexp.MustCompile(`^[0-9]+$`),
+	"slug": regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`),
+}
+
 var (
 	greenBg   = string([]byte{27, 91, 57, 55, 59, 52, 50, 109})
 	whiteBg   = string([]byte{27, 91, 57, 48, 59, 52, 55, 109})
@@ -48,8 +56,23 @@ const (
 	MethodPatch  = "PATCH"
 )
 
+var anyMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+	http.MethodOptions,
+}
+
 var errMethodNotAllowed = errors.New("405")
 var errNotFound = errors.New("404")
 
 var errorNotFoundMessage = []byte(`{"error":"Not found"}`)
 var errorMethodNotAllowedMessage = []byte(`{"error":"method not allowed"}`)
+var errorBadRequestMessage = []byte(`{"error":"bad request"}`)
+var errorServiceUnavailableMessage = []byte(`{"error":"service unavailable"}`)
+var errorTooManyRequestsMessage = []byte(`{"error":"too many requests"}`)
+var errorRequestEntityTooLargeMessage = []byte(`{"error":"request entity too large"}`)
+var errorInternalServerErrorMessage = []byte(`{"error":"internal server error"}`)
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins lists the allowed origins, "*" allows any origin.
+	AllowOrigins []string
+	// AllowMethods defaults to the standard methods when empty.
+	AllowMethods []string
+	// AllowHeaders defaults to the headers requested by the preflight when empty.
+	AllowHeaders     []string
+	AllowCredentials bool
+	// MaxAge is how long a preflight response may be cached, not sent when zero.
+	MaxAge time.Duration
+}
+
+// CORS returns a middleware which sets CORS headers for allowed origins and answers
+// preflight OPTIONS requests with 204. Requests from other origins get no CORS headers.
+func CORS(config CORSConfig) Middleware {
+	methods := config.AllowMethods
+	if len(methods) == 0 {
+		methods = anyMethods
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			allowOrigin := config.allowOrigin(origin)
+			if allowOrigin != "" {
+				w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+				if config.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+			if !preflight {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowOrigin != "" {
+				w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+				if allowHeaders != "" {
+					w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+				} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+					w.Header().Set("Access-Control-Allow-Headers", requested)
+				}
+				if config.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for origin, empty if it is not allowed.
+// The origin is reflected instead of "*" when credentials are allowed, as browsers require.
+func (config CORSConfig) allowOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	for _, allowed := range config.AllowOrigins {
+		if allowed == "*" {
+			if config.AllowCredentials {
+				return origin
+			}
+			return "*"
+		}
+		if allowed == origin {
+			return origin
+		}
+	}
+	return ""
+}
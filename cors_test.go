@@ -0,0 +1,86 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/users/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users"))
+	}))
+	handler := CORS(CORSConfig{
+		AllowOrigins:     []string{"https://example.com"},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost},
+		AllowHeaders:     []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})(rt)
+
+	testTable := []struct {
+		Method  string
+		Headers map[string]string
+		Status  int
+		Expect  map[string]string
+	}{
+		// preflight from an allowed origin
+		{http.MethodOptions, map[string]string{"Origin": "https://example.com", "Access-Control-Request-Method": "POST"}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":      "https://example.com",
+			"Access-Control-Allow-Methods":     "GET, POST",
+			"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Max-Age":           "600",
+			"Vary":                             "Origin",
+		}},
+		// simple request from an allowed origin
+		{http.MethodGet, map[string]string{"Origin": "https://example.com"}, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":  "https://example.com",
+			"Access-Control-Allow-Methods": "",
+			"Vary":                         "Origin",
+		}},
+		// simple request from a disallowed origin
+		{http.MethodGet, map[string]string{"Origin": "https://evil.com"}, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":      "",
+			"Access-Control-Allow-Credentials": "",
+			"Vary":                             "Origin",
+		}},
+		// preflight from a disallowed origin
+		{http.MethodOptions, map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": "POST"}, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":  "",
+			"Access-Control-Allow-Methods": "",
+		}},
+	}
+	for testCase, test := range testTable {
+		req := httptest.NewRequest(test.Method, "/users/", nil)
+		for key, value := range test.Headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, testCase)
+		for key, value := range test.Expect {
+			assert.Equal(t, value, w.Header().Get(key), "#%d %s", testCase, key)
+		}
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	handler := CORS(CORSConfig{AllowOrigins: []string{"*"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://any.com")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	req.Header.Set("Access-Control-Request-Headers", "X-Token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Token", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "DELETE")
+	assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+}
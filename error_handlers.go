@@ -1,20 +1,33 @@
 package router
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+)
 
 type (
 	notFound      struct{}
 	notNotAllowed struct{}
+	badRequest    struct{}
 )
 
 func (nt notFound) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header()["Content-Type"] = []string{"application/json"}
-	w.WriteHeader(http.StatusNotFound)
-	w.Write(errorNotFoundMessage)
+	writeJSON(w, http.StatusNotFound, errorNotFoundMessage)
 }
 
 func (nt notNotAllowed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusMethodNotAllowed, errorMethodNotAllowedMessage)
+}
+
+func (nt badRequest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusBadRequest, errorBadRequestMessage)
+}
+
+// writeJSON writes an already encoded JSON body, setting Content-Length so the
+// response is not chunked.
+func writeJSON(w http.ResponseWriter, code int, body []byte) {
 	w.Header()["Content-Type"] = []string{"application/json"}
-	w.WriteHeader(http.StatusMethodNotAllowed)
-	w.Write(errorMethodNotAllowedMessage)
+	w.Header()["Content-Length"] = []string{strconv.Itoa(len(body))}
+	w.WriteHeader(code)
+	w.Write(body)
 }
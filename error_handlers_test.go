@@ -0,0 +1,30 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHandlersContentLength(t *testing.T) {
+	testTable := []struct {
+		Handler http.Handler
+		Status  int
+		Body    []byte
+	}{
+		{notFound{}, http.StatusNotFound, errorNotFoundMessage},
+		{notNotAllowed{}, http.StatusMethodNotAllowed, errorMethodNotAllowedMessage},
+		{badRequest{}, http.StatusBadRequest, errorBadRequestMessage},
+	}
+	for testCase, test := range testTable {
+		w := httptest.NewRecorder()
+		test.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, test.Status, w.Code, testCase)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), testCase)
+		assert.Equal(t, strconv.Itoa(len(test.Body)), w.Header().Get("Content-Length"), testCase)
+		assert.Equal(t, string(test.Body), w.Body.String(), testCase)
+	}
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strings"
+)
+
+// ETag returns a middleware which buffers 200 responses to GET and HEAD requests, sets a weak
+// ETag computed from the body unless the handler set one, and answers 304 with an empty body
+// when it matches the request's If-None-Match.
+func ETag() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			bw := &bufferedWriter{ResponseWriter: w}
+			next.ServeHTTP(bw, r)
+			if bw.code == 0 {
+				bw.code = http.StatusOK
+			}
+
+			if bw.code == http.StatusOK {
+				if w.Header().Get("ETag") == "" {
+					w.Header().Set("ETag", fmt.Sprintf(`W/"%08x"`, crc32.ChecksumIEEE(bw.buf.Bytes())))
+				}
+				if etagMatch(r.Header.Get("If-None-Match"), w.Header().Get("ETag")) {
+					w.Header().Del("Content-Type")
+					w.Header().Del("Content-Length")
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+			w.WriteHeader(bw.code)
+			w.Write(bw.buf.Bytes())
+		})
+	}
+}
+
+// etagMatch reports whether any of the If-None-Match values weakly matches etag.
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
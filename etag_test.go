@@ -0,0 +1,67 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.Any("/users/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"users":[]}`))
+	}))
+	handler := ETag()(rt)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/", nil))
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `^W/"[0-9a-f]{8}"$`, etag)
+	assert.Equal(t, `{"users":[]}`, w.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/users/", nil)
+	req.Header.Set("If-None-Match", `W/"00000000"`)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"users":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestETagMatch(t *testing.T) {
+	testTable := []struct {
+		IfNoneMatch, ETag string
+		Match             bool
+	}{
+		{"", `W/"a"`, false},
+		{`W/"a"`, `W/"a"`, true},
+		{`"a"`, `W/"a"`, true},
+		{`W/"b", W/"a"`, `W/"a"`, true},
+		{`W/"b"`, `W/"a"`, false},
+		{"*", `W/"a"`, true},
+	}
+	for testCase, test := range testTable {
+		assert.Equal(t, test.Match, etagMatch(test.IfNoneMatch, test.ETag), testCase)
+	}
+}
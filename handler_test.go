@@ -85,3 +85,64 @@ func TestHandlerRegister(t *testing.T) {
 		}
 	}
 }
+
+func TestHandlerHandle(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.Handle([]string{"GET", "POST"}, "/x/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method))
+	}))
+	testTable := []struct {
+		Method string
+		Status int
+	}{
+		{"GET", http.StatusOK},
+		{"POST", http.StatusOK},
+		{"DELETE", http.StatusMethodNotAllowed},
+	}
+	for testCase, test := range testTable {
+		req, _ := http.NewRequest(test.Method, "/x/", nil)
+		testReq := httptest.NewRecorder()
+		rt.ServeHTTP(testReq, req)
+		if testReq.Code != test.Status {
+			t.Errorf("#%d: got status %d, expected %d", testCase, testReq.Code, test.Status)
+			continue
+		}
+		if test.Status == http.StatusOK && testReq.Body.String() != test.Method {
+			t.Errorf("#%d: body not equal", testCase)
+		}
+	}
+}
+
+func TestHandlerHandleInvalidMethod(t *testing.T) {
+	for testCase, methods := range [][]string{{""}, {"get"}, {"GET", "Post"}} {
+		func() {
+			defer func() {
+				if errCase := recover(); errCase == nil {
+					t.Errorf("#%d : expected a panic but nothing happend ", testCase)
+				}
+			}()
+			NewRouter(&RouterOption{}).Handle(methods, "/x/", http.NotFoundHandler())
+		}()
+	}
+}
+
+func TestHandlerAny(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.Any("/any/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for testCase, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"} {
+		req, _ := http.NewRequest(method, "/any/", nil)
+		testReq := httptest.NewRecorder()
+		rt.ServeHTTP(testReq, req)
+		if testReq.Code != http.StatusOK {
+			t.Errorf("#%d: %s got status %d", testCase, method, testReq.Code)
+		}
+	}
+	req, _ := http.NewRequest("KICK", "/any/", nil)
+	testReq := httptest.NewRecorder()
+	rt.ServeHTTP(testReq, req)
+	if testReq.Code != http.StatusMethodNotAllowed {
+		t.Errorf("KICK got status %d", testReq.Code)
+	}
+}
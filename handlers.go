@@ -3,23 +3,42 @@ package router
 import (
 	"fmt"
 	"net/http"
+	"strings"
 )
 
-func (rt router) GET(path string, handler http.Handler) {
+func (rt *router) GET(path string, handler http.Handler) {
 	rt.Register(path, http.MethodGet, handler)
 }
-func (rt router) POST(path string, handler http.Handler) {
+func (rt *router) POST(path string, handler http.Handler) {
 	rt.Register(path, http.MethodPost, handler)
 }
-func (rt router) PUT(path string, handler http.Handler) {
+func (rt *router) PUT(path string, handler http.Handler) {
 	rt.Register(path, http.MethodPut, handler)
 }
-func (rt router) DELETE(path string, handler http.Handler) {
+func (rt *router) DELETE(path string, handler http.Handler) {
 	rt.Register(path, http.MethodDelete, handler)
 }
-func (rt router) PATCH(path string, handler http.Handler) {
+func (rt *router) PATCH(path string, handler http.Handler) {
 	rt.Register(path, http.MethodPatch, handler)
 }
-func (rt router) DELEGATE(path string, method string, handler http.Handler) {
+
+// Any registers handler for every standard method, custom methods still need Register.
+func (rt *router) Any(path string, handler http.Handler) {
+	rt.Handle(anyMethods, path, handler)
+}
+
+func (rt *router) DELEGATE(path string, method string, handler http.Handler) {
 	rt.Register(fmt.Sprintf("%s*/", path), method, handler)
 }
+
+// Handle registers handler for each of methods on path.
+func (rt *router) Handle(methods []string, path string, handler http.Handler) {
+	for _, method := range methods {
+		if method == "" || method != strings.ToUpper(method) {
+			panic(fmt.Sprintf("method %q must be a non-empty uppercase string", method))
+		}
+	}
+	for _, method := range methods {
+		rt.Register(path, method, handler)
+	}
+}
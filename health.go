@@ -0,0 +1,44 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+type healthStatus struct {
+	Status    string    `json:"status"`
+	Errors    []string  `json:"errors,omitempty"`
+	Uptime    string    `json:"uptime"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Health registers a GET route on path which runs checks and reports the result as JSON.
+// It responds 200 when every check passes, otherwise 503 listing the errors of failing checks,
+// along with the router uptime and the current time. Nil checks are skipped.
+// Checks share a context derived from the request and bounded by healthCheckTimeout.
+func (rt *router) Health(path string, checks ...func(ctx context.Context) error) {
+	rt.Register(path, http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		now := time.Now()
+		status := healthStatus{Status: "ok", Uptime: now.Sub(rt.started).String(), Timestamp: now}
+		code := http.StatusOK
+		for _, check := range checks {
+			if check == nil {
+				continue
+			}
+			if err := check(ctx); err != nil {
+				status.Status = "unavailable"
+				status.Errors = append(status.Errors, err.Error())
+				code = http.StatusServiceUnavailable
+			}
+		}
+		body, _ := json.Marshal(status)
+		writeJSON(w, code, body)
+	}))
+}
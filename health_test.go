@@ -0,0 +1,87 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	passing := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("database unreachable") }
+	testTable := []struct {
+		Checks []func(ctx context.Context) error
+		Status int
+		State  string
+		Errors []string
+	}{
+		{nil, http.StatusOK, "ok", nil},
+		{[]func(ctx context.Context) error{nil}, http.StatusOK, "ok", nil},
+		{[]func(ctx context.Context) error{passing, passing}, http.StatusOK, "ok", nil},
+		{[]func(ctx context.Context) error{passing, failing}, http.StatusServiceUnavailable, "unavailable", []string{"database unreachable"}},
+	}
+	for testCase, test := range testTable {
+		router := NewRouter(&RouterOption{})
+		router.Health("/health/", test.Checks...)
+
+		req := httptest.NewRequest(http.MethodGet, "/health/", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, testCase)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), testCase)
+
+		var status healthStatus
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status), testCase)
+		assert.Equal(t, test.State, status.Status, testCase)
+		assert.Equal(t, test.Errors, status.Errors, testCase)
+		assert.NotEmpty(t, status.Uptime, testCase)
+		assert.WithinDuration(t, time.Now(), status.Timestamp, time.Minute, testCase)
+	}
+}
+
+func TestHealthToggle(t *testing.T) {
+	var checkErr error
+	router := NewRouter(&RouterOption{})
+	router.Health("/health/", func(ctx context.Context) error { return checkErr })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	checkErr = errors.New("draining")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"errors":["draining"]`)
+}
+
+func TestHealthCheckDeadline(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	router.Health("/health/", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return errors.New("no deadline")
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/health/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealthContentLength(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	router.Health("/health/")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/", nil))
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+}
@@ -0,0 +1,185 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Middleware wraps an http.Handler, e.g. to wrap the whole router or a single route handler.
+type Middleware func(http.Handler) http.Handler
+
+// RequestLogger returns a middleware which logs method, path, status and latency of every request.
+// Responses with a 5xx status are logged at Error level, everything else at Info level.
+func RequestLogger(l LeveledLoggerInterface) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := newResponseWriter(w)
+			next.ServeHTTP(rw, r)
+			latency := time.Since(start)
+
+			if rw.Status() >= http.StatusInternalServerError {
+				l.Errorf("%s %s %d %s", r.Method, r.URL.Path, rw.Status(), latency)
+				return
+			}
+			l.Infof("%s %s %d %s", r.Method, r.URL.Path, rw.Status(), latency)
+		})
+	}
+}
+
+// Timeout returns a middleware which cancels the request context after d and responds 503
+// when the handler has not finished by then. The handler writes into a buffer which is
+// only copied to the client if it finishes in time, so a late handler can not write.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				for key, values := range tw.header {
+					w.Header()[key] = values
+				}
+				if tw.code == 0 {
+					tw.code = http.StatusOK
+				}
+				w.WriteHeader(tw.code)
+				w.Write(tw.buf.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				writeJSON(w, http.StatusServiceUnavailable, errorServiceUnavailableMessage)
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers a response until Timeout decides whether it is sent.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	code     int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(b)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+// MaxBodySize returns a middleware which limits request bodies to n bytes. Requests declaring
+// a larger Content-Length get 413 without reaching the handler, other bodies fail on read
+// once the limit is exceeded.
+func MaxBodySize(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				writeJSON(w, http.StatusRequestEntityTooLarge, errorRequestEntityTooLargeMessage)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MethodOverride returns a middleware which lets POST requests from HTML forms use another
+// standard method given in the X-HTTP-Method-Override header or the _method form field.
+// The form field is only read from urlencoded bodies, multipart bodies are left to the handler.
+// It must wrap the router, not a route handler, so the rewritten method is used for matching.
+func MethodOverride() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				method := r.Header.Get("X-HTTP-Method-Override")
+				if method == "" && isURLEncodedForm(r) {
+					method = r.PostFormValue("_method")
+				}
+				method = strings.ToUpper(method)
+				for _, known := range anyMethods {
+					if method == known {
+						r.Method = method
+						break
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isURLEncodedForm reports whether the request body is an application/x-www-form-urlencoded form.
+func isURLEncodedForm(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
+
+// RecoveryWithHandler returns a middleware which recovers panics from the handler, passes the
+// request, the recovered value and the stack trace to onPanic and responds 500.
+// http.ErrAbortHandler is re-panicked so net/http can abort the response as intended.
+func RecoveryWithHandler(onPanic func(r *http.Request, recovered interface{}, stack []byte)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				if onPanic != nil {
+					onPanic(r, recovered, debug.Stack())
+				}
+				writeJSON(w, http.StatusInternalServerError, errorInternalServerErrorMessage)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLogger struct {
+	lines []string
+}
+
+func (l *fakeLogger) Debugf(format string, v ...interface{}) {
+	l.lines = append(l.lines, "DEBUG "+fmt.Sprintf(format, v...))
+}
+func (l *fakeLogger) Errorf(format string, v ...interface{}) {
+	l.lines = append(l.lines, "ERROR "+fmt.Sprintf(format, v...))
+}
+func (l *fakeLogger) Infof(format string, v ...interface{}) {
+	l.lines = append(l.lines, "INFO "+fmt.Sprintf(format, v...))
+}
+func (l *fakeLogger) Warnf(format string, v ...interface{}) {
+	l.lines = append(l.lines, "WARN "+fmt.Sprintf(format, v...))
+}
+
+func TestRequestLogger(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/ok/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	rt.POST("/fail/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	testTable := []struct {
+		Method, Path string
+		Prefix       string
+	}{
+		{http.MethodGet, "/ok/", "INFO GET /ok/ 200 "},
+		{http.MethodGet, "/missing/", "INFO GET /missing/ 404 "},
+		{http.MethodPost, "/fail/", "ERROR POST /fail/ 500 "},
+	}
+	for _, test := range testTable {
+		logger := &fakeLogger{}
+		handler := RequestLogger(logger)(rt)
+
+		req := httptest.NewRequest(test.Method, test.Path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if assert.Len(t, logger.lines, 1) {
+			assert.True(t, strings.HasPrefix(logger.lines[0], test.Prefix), logger.lines[0])
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	lateWrite := make(chan error, 1)
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/fast/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "fast")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("fast"))
+	}))
+	rt.GET("/slow/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		_, err := w.Write([]byte("slow"))
+		lateWrite <- err
+	}))
+	handler := Timeout(20 * time.Millisecond)(rt)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast/", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "fast", w.Header().Get("X-Handler"))
+	assert.Equal(t, "fast", w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, string(errorServiceUnavailableMessage), w.Body.String())
+	assert.Equal(t, http.ErrHandlerTimeout, <-lateWrite)
+}
+
+func TestMaxBodySize(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.POST("/upload/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusRequestEntityTooLarge, errorRequestEntityTooLargeMessage)
+			return
+		}
+		w.Write(body)
+	}))
+	handler := MaxBodySize(8)(rt)
+
+	testTable := []struct {
+		Body    string
+		Chunked bool
+		Status  int
+	}{
+		{"small", false, http.StatusOK},
+		{"exactly8", false, http.StatusOK},
+		{"much too large", false, http.StatusRequestEntityTooLarge},
+		{"much too large", true, http.StatusRequestEntityTooLarge},
+	}
+	for testCase, test := range testTable {
+		req := httptest.NewRequest(http.MethodPost, "/upload/", strings.NewReader(test.Body))
+		if test.Chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, testCase)
+		if test.Status == http.StatusOK {
+			assert.Equal(t, test.Body, w.Body.String(), testCase)
+		}
+	}
+}
+
+func TestMethodOverride(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.Any("/users/:id/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method))
+	}))
+	handler := MethodOverride()(rt)
+
+	testTable := []struct {
+		Method   string
+		Header   string
+		Form     string
+		Expected string
+	}{
+		{http.MethodPost, "DELETE", "", "DELETE"},
+		{http.MethodPost, "patch", "", "PATCH"},
+		{http.MethodPost, "", "_method=PUT", "PUT"},
+		{http.MethodPost, "KICK", "", "POST"},
+		{http.MethodPost, "", "", "POST"},
+		{http.MethodGet, "DELETE", "", "GET"},
+	}
+	for testCase, test := range testTable {
+		req := httptest.NewRequest(test.Method, "/users/12/", strings.NewReader(test.Form))
+		if test.Header != "" {
+			req.Header.Set("X-HTTP-Method-Override", test.Header)
+		}
+		if test.Form != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, test.Expected, w.Body.String(), testCase)
+	}
+
+	rt.DELETE("/posts/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("deleted"))
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/posts/", nil)
+	req.Header.Set("X-HTTP-Method-Override", "DELETE")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "deleted", w.Body.String())
+}
+
+func TestMethodOverrideLeavesMultipartBody(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.Any("/uploads/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		part, err := reader.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		value, _ := ioutil.ReadAll(part)
+		fmt.Fprintf(w, "%s %s=%s", r.Method, part.FormName(), value)
+	}))
+	handler := MethodOverride()(rt)
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	form.WriteField("_method", "DELETE")
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/uploads/", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "POST _method=DELETE", w.Body.String())
+}
+
+func TestTimeoutPerRoute(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/fast/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("fast"))
+	}))
+	rt.GET("/slow/", Timeout(10*time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("slow"))
+	})))
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fast", w.Body.String())
+
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestRecoveryWithHandler(t *testing.T) {
+	var (
+		gotPath      string
+		gotRecovered interface{}
+		gotStack     []byte
+	)
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/panic/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	rt.GET("/ok/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	handler := RecoveryWithHandler(func(r *http.Request, recovered interface{}, stack []byte) {
+		gotPath, gotRecovered, gotStack = r.URL.Path, recovered, stack
+	})(rt)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, string(errorInternalServerErrorMessage), w.Body.String())
+	assert.Equal(t, "/panic/", gotPath)
+	assert.Equal(t, "boom", gotRecovered)
+	assert.Contains(t, string(gotStack), "TestRecoveryWithHandler")
+
+	gotRecovered = nil
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, gotRecovered)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		RecoveryWithHandler(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
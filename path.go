@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	pathpkg "path"
 	"strings"
 )
 
@@ -20,6 +21,184 @@ func (path *Path) Validate() {
 	if !(strings.HasPrefix(path.String(), "/") && (strings.HasSuffix(path.String(), "/") || path.String() == "/")) {
 		panic(fmt.Sprintf("path %s must start with / and end with /", path.String()))
 	}
+	// ? only marks the last segment as an optional :param
+	segments := strings.Split(path.String(), "/")
+	for i, segment := range segments {
+		j := strings.Index(segment, "?")
+		if j < 0 {
+			continue
+		}
+		if i != len(segments)-2 || !strings.HasPrefix(segment, ":") || j != len(segment)-1 {
+			panic(fmt.Sprintf("path %s may only use ? at the end of its last :param segment", path.String()))
+		}
+	}
+}
+
+// requestPath normalizes a request path to the registered form, starting and ending with /.
+func requestPath(reqPath string) string {
+	if reqPath == "" {
+		return "/"
+	}
+	if !validateRequestPathRegex.MatchString(reqPath) {
+		reqPath = fmt.Sprintf("%s/", reqPath)
+	}
+	return reqPath
+}
+
+// cleanPath collapses duplicate slashes and resolves . and .. segments, keeping a trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	cleaned := pathpkg.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// matches reports whether the param route path matches the request path split by /.
+// It walks the route segments in place, so a mismatch does not allocate.
+func (path Path) matches(splicedReq []string) bool {
+	rest, last := path.String(), false
+	for _, value := range splicedReq {
+		if last {
+			return false
+		}
+		segment := rest
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			segment, rest = rest[:i], rest[i+1:]
+		} else {
+			last = true
+		}
+		if !matchSegment(segment, value) {
+			return false
+		}
+	}
+	return last
+}
+
+// covers reports whether the delegate prefix path matches the request path split by /,
+// either exactly or as one of its ancestors.
+func (prefix Path) covers(splicedReq []string) bool {
+	// prefix ends with / so the segment after its last / stands for the remainder
+	rest := prefix.String()
+	for _, value := range splicedReq {
+		i := strings.IndexByte(rest, '/')
+		if i < 0 {
+			return true
+		}
+		if !matchSegment(rest[:i], value) {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+	return false
+}
+
+// overlaps reports whether some request path could match both the delegate prefix
+// and route, a static or param route path.
+func (prefix Path) overlaps(route Path) bool {
+	splicedPrefix := strings.Split(prefix.String(), "/")
+	splicedRoute := strings.Split(route.String(), "/")
+	n := len(splicedPrefix) - 1
+	if n >= len(splicedRoute) {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if !segmentsOverlap(splicedPrefix[i], splicedRoute[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentsOverlap reports whether some request path segment could match both route segments.
+func segmentsOverlap(a, b string) bool {
+	if a == b || a == "*" || b == "*" {
+		return true
+	}
+	constraintA, okA := segmentConstraint(a)
+	constraintB, okB := segmentConstraint(b)
+	switch {
+	case okA && okB:
+		return true
+	case okA:
+		return namedConstraints[constraintA].MatchString(b)
+	case okB:
+		return namedConstraints[constraintB].MatchString(a)
+	}
+	return false
+}
+
+// matchSegment reports whether a request path segment matches a route segment,
+// which is either static, a * param or a *<constraint> param.
+func matchSegment(pattern, value string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if constraint, ok := segmentConstraint(pattern); ok {
+		return namedConstraints[constraint].MatchString(value)
+	}
+	return pattern == value
+}
+
+// segmentConstraint returns the constraint name of a *<constraint> route segment.
+// Any other segment starting with * is static.
+func segmentConstraint(segment string) (string, bool) {
+	if !strings.HasPrefix(segment, "*<") || !strings.HasSuffix(segment, ">") {
+		return "", false
+	}
+	constraint := segment[2 : len(segment)-1]
+	_, ok := namedConstraints[constraint]
+	return constraint, ok
+}
+
+// segmentRank orders route segments by precedence: static, constrained param, then param.
+func segmentRank(segment string) int {
+	if segment == "*" {
+		return 2
+	}
+	if _, ok := segmentConstraint(segment); ok {
+		return 1
+	}
+	return 0
+}
+
+// paramPattern replaces every :param segment of path with *, or *<constraint> for :param<constraint>,
+// and returns the param names by segment index.
+func paramPattern(path Path) (Path, []string) {
+	arr := strings.Split(path.String(), "/")
+	names := make([]string, len(arr))
+	for i := 0; i < len(arr); i++ {
+		if strings.HasPrefix(arr[i], ":") {
+			name := arr[i][1:]
+			arr[i] = "*"
+			if j := strings.Index(name, "<"); j >= 0 && strings.HasSuffix(name, ">") {
+				constraint := name[j+1 : len(name)-1]
+				if _, ok := namedConstraints[constraint]; !ok {
+					panic(fmt.Sprintf("unknown param constraint %s in path %s", constraint, path))
+				}
+				arr[i] = "*<" + constraint + ">"
+				name = name[:j]
+			}
+			names[i] = name
+		}
+	}
+	return Path(strings.Join(arr, "/")), names
+}
+
+// outranks reports whether path should be matched before other: at the first segment
+// where their kinds differ, static wins over a constrained param, which wins over a param.
+func (path Path) outranks(other Path) bool {
+	a := strings.Split(path.String(), "/")
+	b := strings.Split(other.String(), "/")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if rankA, rankB := segmentRank(a[i]), segmentRank(b[i]); rankA != rankB {
+			return rankA < rankB
+		}
+	}
+	return false
 }
 
 // // isParamKey checks if param key is duplicated
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const rateLimitSweepInterval = time.Minute
+
+type (
+	// rateLimiter keeps a token bucket per client IP.
+	rateLimiter struct {
+		mu        sync.Mutex
+		rps       float64
+		burst     float64
+		buckets   map[string]*tokenBucket
+		lastSweep time.Time
+		now       func() time.Time
+	}
+	tokenBucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// RateLimit returns a middleware which allows each client IP rps requests per second
+// with bursts of up to burst requests, answering 429 with a Retry-After header otherwise.
+// Buckets of idle clients are evicted once they would have refilled completely.
+// It panics if rps is not positive or burst is less than 1.
+func RateLimit(rps float64, burst int) Middleware {
+	limiter := newRateLimiter(rps, burst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, retryAfter := limiter.allow(remoteIP(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				writeJSON(w, http.StatusTooManyRequests, errorTooManyRequestsMessage)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if rps <= 0 || math.IsNaN(rps) || burst < 1 {
+		panic(fmt.Sprintf("rate limit needs a positive rps and a burst of at least 1, got %v and %d", rps, burst))
+	}
+	return &rateLimiter{
+		rps:     rps,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the bucket of key, or reports how long until one is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rps)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rps * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops buckets which have refilled completely, they are equal to a new bucket.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rps >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// remoteIP returns the host part of r.RemoteAddr.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	handler := RateLimit(1, 2)(rt)
+
+	testTable := []struct {
+		RemoteAddr string
+		Status     int
+		RetryAfter string
+	}{
+		{"10.0.0.1:1234", http.StatusOK, ""},
+		{"10.0.0.1:1235", http.StatusOK, ""},
+		{"10.0.0.1:1236", http.StatusTooManyRequests, "1"},
+		{"10.0.0.1:1237", http.StatusTooManyRequests, "1"},
+		{"10.0.0.2:1234", http.StatusOK, ""},
+	}
+	for testCase, test := range testTable {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = test.RemoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, testCase)
+		assert.Equal(t, test.RetryAfter, w.Header().Get("Retry-After"), testCase)
+	}
+}
+
+func TestRateLimitInvalidArguments(t *testing.T) {
+	assert.Panics(t, func() { RateLimit(0, 1) })
+	assert.Panics(t, func() { RateLimit(-1, 1) })
+	assert.Panics(t, func() { RateLimit(math.NaN(), 1) })
+	assert.Panics(t, func() { RateLimit(1, 0) })
+	assert.NotPanics(t, func() { RateLimit(0.5, 1) })
+}
+
+func TestRateLimiterRefillAndSweep(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(2, 1)
+	limiter.now = func() time.Time { return now }
+
+	ok, _ := limiter.allow("a")
+	assert.True(t, ok)
+	ok, retryAfter := limiter.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = limiter.allow("a")
+	assert.True(t, ok)
+	assert.Len(t, limiter.buckets, 1)
+
+	now = now.Add(rateLimitSweepInterval)
+	limiter.allow("b")
+	assert.Len(t, limiter.buckets, 1)
+	assert.Contains(t, limiter.buckets, "b")
+}
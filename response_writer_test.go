@@ -0,0 +1,58 @@
+package router
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	var w interface{} = newResponseWriter(httptest.NewRecorder())
+	_, ok := w.(http.Flusher)
+	assert.True(t, ok, "http.Flusher")
+	_, ok = w.(http.Hijacker)
+	assert.True(t, ok, "http.Hijacker")
+	_, ok = w.(http.Pusher)
+	assert.True(t, ok, "http.Pusher")
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	w := newResponseWriter(recorder)
+	w.Write([]byte("chunk"))
+	w.Flush()
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, http.StatusOK, w.Status())
+	assert.Equal(t, 5, w.Size())
+}
+
+func TestResponseWriterNotSupported(t *testing.T) {
+	w := newResponseWriter(httptest.NewRecorder())
+	_, _, err := w.Hijack()
+	assert.Equal(t, http.ErrNotSupported, err)
+	assert.Equal(t, http.ErrNotSupported, w.Push("/app.js", nil))
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := newResponseWriter(w).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		buf.Flush()
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "hijacked", string(body))
+}
@@ -1,28 +1,60 @@
 package router
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type (
 	Router interface {
 		ServeHTTP(http.ResponseWriter, *http.Request)
 		Register(path, method string, handler http.Handler)
+		Handle(methods []string, path string, handler http.Handler)
 		GET(path string, handler http.Handler)
 		POST(path string, handler http.Handler)
 		PUT(path string, handler http.Handler)
 		DELETE(path string, handler http.Handler)
 		PATCH(path string, handler http.Handler)
+		Any(path string, handler http.Handler)
+		DELEGATE(path string, method string, handler http.Handler)
+		PathPrefix(prefix string) http.Handler
+		Health(path string, checks ...func(ctx context.Context) error)
+		Reverse(pattern string, values ...string) (string, error)
+		MethodsFor(path string) []string
+		Walk(fn func(method, path string))
+		ListenAndServe(addr string) error
+		Shutdown(ctx context.Context) error
 	}
 	router struct {
 		notFoundHandler  http.Handler
 		methodNotAllowed http.Handler
+		constraintFailed http.Handler
 		routes           groupOfRoutes
 		routesWithParams groupOfRoutes
+		delegates        groupOfRoutes
+		paramRoutes      []Path // keys of routesWithParams in matching order
+		methods          map[Method]bool
+		paramNames       map[Path][]string // param names of routesWithParams by segment index
+		delegateNames    map[Path][]string // param names of delegate prefixes by segment index
+		paramConstraints map[string]func(value string) bool
+		preRouteHooks    []func(*http.Request)
+		cleanPath        bool
+		redirectClean    bool
 		logf             LeveledLoggerInterface
+		server           *http.Server
+		serverMu         sync.Mutex
+		serverClosed     bool
+		inFlightMu       sync.Mutex
+		inFlight         int
+		idle             chan struct{} // closed once inFlight drops back to zero
+		started          time.Time
 	}
 
 	groupOfRoutes map[Path]map[Method]http.Handler
@@ -32,50 +64,122 @@ type (
 	RouterOption struct {
 		NotFoundHandler  http.Handler
 		MethodNotAllowed http.Handler
-		Logf             LeveledLoggerInterface
+		// ParamConstraints validates URL params by name (e.g. "version").
+		// A matched route with a param failing its constraint is served by
+		// ConstraintFailedHandler instead of the route handler.
+		ParamConstraints        map[string]func(value string) bool
+		ConstraintFailedHandler http.Handler
+		// PreRouteHooks run in order before route matching, e.g. to rewrite the method or path.
+		PreRouteHooks []func(*http.Request)
+		// CleanPath collapses duplicate slashes and resolves . and .. in request paths before matching.
+		// With RedirectCleanPath, GET and HEAD requests are redirected to the cleaned path with 301 instead.
+		CleanPath         bool
+		RedirectCleanPath bool
+		Logf              LeveledLoggerInterface
 	}
 )
 
 func NewRouter(opts *RouterOption) Router {
 	var notFoundHandler notFound
 	var methodNotAllowedHandler notNotAllowed
+	var constraintFailedHandler badRequest
 
 	r := router{
 		notFoundHandler:  notFoundHandler,
 		methodNotAllowed: methodNotAllowedHandler,
+		constraintFailed: constraintFailedHandler,
 		routes:           make(groupOfRoutes),
+		paramNames:       make(map[Path][]string),
+		delegateNames:    make(map[Path][]string),
+		methods:          make(map[Method]bool),
+		started:          time.Now(),
 	}
-	if opts == nil || opts.MethodNotAllowed != nil {
-		r.notFoundHandler = opts.MethodNotAllowed
+	if opts != nil && opts.MethodNotAllowed != nil {
+		r.methodNotAllowed = opts.MethodNotAllowed
 	}
-	if opts == nil || opts.NotFoundHandler != nil {
+	if opts != nil && opts.NotFoundHandler != nil {
 		r.notFoundHandler = opts.NotFoundHandler
 	}
-	// if opts == nil || nil != opts.Logf {
-	// 	r.logf = opts.Logf
-	// }
+	if opts != nil && opts.ConstraintFailedHandler != nil {
+		r.constraintFailed = opts.ConstraintFailedHandler
+	}
+	if opts != nil {
+		r.paramConstraints = opts.ParamConstraints
+		r.preRouteHooks = opts.PreRouteHooks
+		r.cleanPath = opts.CleanPath
+		r.redirectClean = opts.RedirectCleanPath
+	}
+	if opts != nil && opts.Logf != nil {
+		r.logf = opts.Logf
+	}
 	r.routes = groupOfRoutes{}
 	r.routesWithParams = groupOfRoutes{}
+	r.delegates = groupOfRoutes{}
 	return &r
 }
 
 var ErrRouteNotFound = errors.New("route not found")
+var ErrReverseValues = errors.New("values do not match pattern params")
 
 func (rt *router) Register(p, m string, handler http.Handler) {
 	path := Path(p)
 	method := Method(m)
 	path.Validate()
+	rt.methods[method] = true
+	// if its last segment is an optional param, register the route with and without it
+	if segments := strings.Split(path.String(), "/"); len(segments) > 2 {
+		last := segments[len(segments)-2]
+		if strings.HasPrefix(last, ":") && strings.HasSuffix(last, "?") {
+			segments[len(segments)-2] = strings.TrimSuffix(last, "?")
+			rt.Register(strings.Join(segments[:len(segments)-2], "/")+"/", m, handler)
+			rt.Register(strings.Join(segments, "/"), m, handler)
+			return
+		}
+	}
+	// if its delegate route
+	if strings.HasSuffix(path.String(), "*/") {
+		prefix, names := paramPattern(Path(strings.TrimSuffix(path.String(), "*/")))
+		if registered, ok := rt.delegateNames[prefix]; !ok {
+			rt.delegateNames[prefix] = names
+		} else if pattern(prefix, registered) != pattern(prefix, names) {
+			panic(fmt.Sprintf("delegate %s*/ conflicts with %s*/ registered before", pattern(prefix, names), pattern(prefix, registered)))
+		}
+		t := rt.delegates
+		if _, ok := t[prefix][method]; ok {
+			panic(fmt.Sprintf("delegate %s with method %s already registered", prefix, method))
+		}
+		if t[prefix] == nil {
+			t[prefix] = make(map[Method]http.Handler)
+		}
+		t[prefix][method] = handler
+		var overlapping []string
+		for route := range rt.routes {
+			if prefix.overlaps(route) {
+				overlapping = append(overlapping, route.String())
+			}
+		}
+		for _, route := range rt.paramRoutes {
+			if prefix.overlaps(route) {
+				overlapping = append(overlapping, pattern(route, rt.paramNames[route]))
+			}
+		}
+		sort.Strings(overlapping)
+		for _, route := range overlapping {
+			rt.warnf("route %s overlaps delegate %s*/, the route takes precedence", route, pattern(prefix, rt.delegateNames[prefix]))
+		}
+		return
+	}
 	// if its param route
 	if strings.ContainsAny(path.String(), ":") {
 		//register with params
-		//replace every word begans with : with *
-		arr := strings.Split(path.String(), "/")
-		for i := 0; i < len(arr); i++ {
-			if strings.HasPrefix(arr[i], ":") {
-				arr[i] = "*"
-			}
+		var names []string
+		path, names = paramPattern(path)
+		if registered, ok := rt.paramNames[path]; !ok {
+			rt.paramNames[path] = names
+			rt.insertParamRoute(path)
+		} else if pattern(path, registered) != pattern(path, names) {
+			panic(fmt.Sprintf("route %s conflicts with %s registered before", pattern(path, names), pattern(path, registered)))
 		}
-		path = Path(strings.Join(arr, "/"))
 		t := rt.routesWithParams
 		if _, ok := t[Path(path)][Method(method)]; ok {
 			panic(fmt.Sprintf("route %s with method %s already registered", path, method))
@@ -85,6 +189,7 @@ func (rt *router) Register(p, m string, handler http.Handler) {
 		}
 		t[Path(path)][Method(method)] = handler
 		rt.routesWithParams = t
+		rt.warnDelegateOverlaps(path)
 	} else {
 		t := rt.routes
 		if _, ok := t[Path(path)][Method(method)]; ok {
@@ -96,56 +201,281 @@ func (rt *router) Register(p, m string, handler http.Handler) {
 
 		t[Path(path)][Method(method)] = handler
 		rt.routes = t
+		rt.warnDelegateOverlaps(path)
 	}
 }
 
-func (rt router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	reqPath := r.URL.Path
-	if reqPath == "" {
-		reqPath = "/"
+// warnDelegateOverlaps warns about every delegate which could serve requests matching route.
+func (rt *router) warnDelegateOverlaps(route Path) {
+	var prefixes []string
+	for prefix := range rt.delegates {
+		if prefix.overlaps(route) {
+			prefixes = append(prefixes, pattern(prefix, rt.delegateNames[prefix]))
+		}
 	}
-	if reqPath != "/" && len(reqPath) > 1 {
-		if !validateRequestPathRegex.MatchString(reqPath) {
-			reqPath = fmt.Sprintf("%s/", reqPath)
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		rt.warnf("route %s overlaps delegate %s*/, the route takes precedence", pattern(route, rt.paramNames[route]), prefix)
+	}
+}
+
+// warnf logs a warning through RouterOption.Logf when one is set.
+func (rt *router) warnf(format string, v ...interface{}) {
+	if rt.logf != nil {
+		rt.logf.Warnf(format, v...)
+	}
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.beginRequest()
+	defer rt.endRequest()
+
+	for _, hook := range rt.preRouteHooks {
+		hook(r)
+	}
+	if rt.cleanPath {
+		if cleaned := cleanPath(r.URL.Path); cleaned != r.URL.Path {
+			if rt.redirectClean && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+				target := url.URL{Path: cleaned, RawQuery: r.URL.RawQuery}
+				http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+				return
+			}
+			r.URL.Path = cleaned
 		}
 	}
+	reqPath := requestPath(r.URL.Path)
 
+	// a method never registered can not match, only tell 404 from 405
+	if !rt.methods[Method(r.Method)] {
+		if rt.hasRoute(reqPath) {
+			w.Header()["Allow"] = []string{strings.Join(rt.MethodsFor(reqPath), ", ")}
+			rt.methodNotAllowed.ServeHTTP(w, r)
+			return
+		}
+		rt.notFoundHandler.ServeHTTP(w, r)
+		return
+	}
 	// 1 check main routes
+	_, allowed := rt.routes[Path(reqPath)]
 	if handler, ok := rt.routes[Path(reqPath)][Method(r.Method)]; ok {
 		handler.ServeHTTP(w, r)
 		return
 	}
-	// 2 check routes with params
-	for path, handlers := range rt.routesWithParams {
-		splicedReq := strings.Split(reqPath, "/")
-		splicedPath := strings.Split(path.String(), "/")
-		if len(splicedReq) != len(splicedPath) {
-			continue
-		}
-		ok := true
-		for i := 0; i < len(splicedReq); i++ {
-			if splicedPath[i] == "*" || splicedReq[i] == splicedPath[i] {
+	// 2 check routes with params, a matching path without this method falls through to the next candidate
+	splicedReq := strings.Split(reqPath, "/")
+	for _, path := range rt.paramRoutes {
+		handlers := rt.routesWithParams[path]
+		if path.matches(splicedReq) {
+			handler := handlers[Method(r.Method)]
+			if nil == handler {
+				allowed = true
 				continue
-			} else {
-				ok = false
-				break
 			}
-		}
-		if ok {
-			handler := handlers[Method(r.Method)]
-			if nil != handler {
-				handler.ServeHTTP(w, r)
+			if !rt.checkConstraints(rt.paramNames[path], splicedReq) {
+				rt.constraintFailed.ServeHTTP(w, r)
 				return
-			} else {
-				rt.methodNotAllowed.ServeHTTP(w, r)
+			}
+			handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	// 3 check delegates
+	if prefix, ok := rt.delegatePrefix(reqPath); ok {
+		if handler := rt.delegates[prefix][Method(r.Method)]; handler != nil {
+			if !rt.checkConstraints(rt.delegateNames[prefix], splicedReq) {
+				rt.constraintFailed.ServeHTTP(w, r)
 				return
 			}
+			handler.ServeHTTP(w, r)
+			return
 		}
+		allowed = true
+	}
+	// 4 path registered with other methods
+	if allowed {
+		w.Header()["Allow"] = []string{strings.Join(rt.MethodsFor(reqPath), ", ")}
+		rt.methodNotAllowed.ServeHTTP(w, r)
+		return
 	}
 	rt.notFoundHandler.ServeHTTP(w, r)
 
 }
 
+// hasRoute reports whether any route matches the request path, whatever its method.
+func (rt *router) hasRoute(reqPath string) bool {
+	if _, ok := rt.routes[Path(reqPath)]; ok {
+		return true
+	}
+	splicedReq := strings.Split(reqPath, "/")
+	for _, path := range rt.paramRoutes {
+		if path.matches(splicedReq) {
+			return true
+		}
+	}
+	for prefix := range rt.delegates {
+		if prefix.covers(splicedReq) {
+			return true
+		}
+	}
+	return false
+}
+
+// MethodsFor returns the sorted methods registered for the routes matching the request path,
+// nil if no route matches.
+func (rt *router) MethodsFor(path string) []string {
+	reqPath := requestPath(path)
+	seen := make(map[Method]bool)
+	for method := range rt.routes[Path(reqPath)] {
+		seen[method] = true
+	}
+	splicedReq := strings.Split(reqPath, "/")
+	for _, path := range rt.paramRoutes {
+		if path.matches(splicedReq) {
+			for method := range rt.routesWithParams[path] {
+				seen[method] = true
+			}
+		}
+	}
+	if handlers, ok := rt.matchDelegate(reqPath); ok {
+		for method := range handlers {
+			seen[method] = true
+		}
+	}
+
+	var methods []string
+	for method := range seen {
+		methods = append(methods, string(method))
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Walk calls fn once for every registered route, sorted by path and method.
+// Params are reported by their registered :name and delegates end with */.
+func (rt *router) Walk(fn func(method, path string)) {
+	type route struct {
+		method, path string
+	}
+	var routes []route
+	for path, handlers := range rt.routes {
+		for method := range handlers {
+			routes = append(routes, route{string(method), path.String()})
+		}
+	}
+	for path, handlers := range rt.routesWithParams {
+		for method := range handlers {
+			routes = append(routes, route{string(method), pattern(path, rt.paramNames[path])})
+		}
+	}
+	for prefix, handlers := range rt.delegates {
+		for method := range handlers {
+			routes = append(routes, route{string(method), pattern(prefix, rt.delegateNames[prefix]) + "*/"})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].path != routes[j].path {
+			return routes[i].path < routes[j].path
+		}
+		return routes[i].method < routes[j].method
+	})
+	for _, r := range routes {
+		fn(r.method, r.path)
+	}
+}
+
+// pattern puts the registered :name back in place of every * segment of path.
+func pattern(path Path, names []string) string {
+	segments := strings.Split(path.String(), "/")
+	for i, name := range names {
+		if name != "" {
+			segments[i] = ":" + name + strings.TrimPrefix(segments[i], "*")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// insertParamRoute adds path to paramRoutes before the first route it outranks,
+// so static segments are tried before params and equal routes keep registration order.
+func (rt *router) insertParamRoute(path Path) {
+	i := 0
+	for ; i < len(rt.paramRoutes); i++ {
+		if path.outranks(rt.paramRoutes[i]) {
+			break
+		}
+	}
+	rt.paramRoutes = append(rt.paramRoutes, "")
+	copy(rt.paramRoutes[i+1:], rt.paramRoutes[i:])
+	rt.paramRoutes[i] = path
+}
+
+// matchDelegate returns the handlers of the longest delegate prefix matching reqPath.
+// A delegate matches its prefix itself as well as any path below it.
+func (rt *router) matchDelegate(reqPath string) (map[Method]http.Handler, bool) {
+	prefix, ok := rt.delegatePrefix(reqPath)
+	return rt.delegates[prefix], ok
+}
+
+// delegatePrefix returns the longest delegate prefix matching reqPath,
+// preferring static segments over params between prefixes of equal length.
+func (rt *router) delegatePrefix(reqPath string) (Path, bool) {
+	splicedReq := strings.Split(reqPath, "/")
+	var matched Path
+	longest := -1
+	for prefix := range rt.delegates {
+		if !prefix.covers(splicedReq) {
+			continue
+		}
+		if n := strings.Count(prefix.String(), "/"); n > longest || n == longest && prefix.outranks(matched) {
+			matched = prefix
+			longest = n
+		}
+	}
+	return matched, longest >= 0
+}
+
+// PathPrefix returns a handler which strips prefix from the request path before routing,
+// so the router can be mounted inside an http.ServeMux, e.g. mux.Handle("/v1/", rt.PathPrefix("/v1/")).
+func (rt *router) PathPrefix(prefix string) http.Handler {
+	return http.StripPrefix(strings.TrimSuffix(prefix, "/"), rt)
+}
+
+// Reverse fills the :param and * segments of pattern with values in order,
+// e.g. Reverse("/users/:id/files/*/", "1", "a") returns "/users/1/files/a/".
+// Values are path escaped and must be non-empty without a /.
+func (rt *router) Reverse(pattern string, values ...string) (string, error) {
+	segments := strings.Split(pattern, "/")
+	var params []int
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || segment == "*" {
+			params = append(params, i)
+		}
+	}
+	if len(params) != len(values) {
+		return "", fmt.Errorf("%w: %s has %d params, got %d values", ErrReverseValues, pattern, len(params), len(values))
+	}
+	for i, index := range params {
+		if values[i] == "" || strings.Contains(values[i], "/") {
+			return "", fmt.Errorf("%w: %q does not fit in one segment of %s", ErrReverseValues, values[i], pattern)
+		}
+		segments[index] = url.PathEscape(values[i])
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// checkConstraints reports whether every named param of a route, given by segment index,
+// satisfies its constraint.
+func (rt *router) checkConstraints(names []string, values []string) bool {
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		if constraint, ok := rt.paramConstraints[name]; ok && !constraint(values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // 	// // prepare request path
 // 	// reqPath := prepareRequestPath(r.URL.Path)
 // 	// // get routes
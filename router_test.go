@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -51,3 +52,670 @@ func TestMatchPath(t *testing.T) {
 		assert.Equal(t, test.Path, string(data))
 	}
 }
+
+func TestParamConstraints(t *testing.T) {
+	versions := map[string]bool{"v1": true, "v2": true}
+	testTable := []struct {
+		Option      *RouterOption
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{&RouterOption{}, "/api/v99/users/", http.StatusOK, "users"},
+		{&RouterOption{ParamConstraints: map[string]func(string) bool{"version": func(v string) bool { return versions[v] }}}, "/api/v1/users/", http.StatusOK, "users"},
+		{&RouterOption{ParamConstraints: map[string]func(string) bool{"version": func(v string) bool { return versions[v] }}}, "/api/v99/users/", http.StatusBadRequest, string(errorBadRequestMessage)},
+		{&RouterOption{
+			ParamConstraints: map[string]func(string) bool{"version": func(v string) bool { return versions[v] }},
+			ConstraintFailedHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotAcceptable)
+				w.Write([]byte("unsupported version"))
+			}),
+		}, "/api/v99/users/", http.StatusNotAcceptable, "unsupported version"},
+	}
+	for _, test := range testTable {
+		router := NewRouter(test.Option)
+		router.GET("/api/:version/users/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("users"))
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code)
+		assert.Equal(t, test.Body, w.Body.String())
+	}
+}
+
+func TestDelegateParamConstraints(t *testing.T) {
+	router := NewRouter(&RouterOption{
+		ParamConstraints: map[string]func(string) bool{"version": func(v string) bool { return v == "v1" }},
+	})
+	router.DELEGATE("/api/:version/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("api"))
+	}))
+
+	testTable := []struct {
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{"/api/v1/users/", http.StatusOK, "api"},
+		{"/api/v1/", http.StatusOK, "api"},
+		{"/api/v99/users/", http.StatusBadRequest, string(errorBadRequestMessage)},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+	}
+}
+
+func TestParamNamesConflict(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	router.GET("/api/:version/users/", http.NotFoundHandler())
+	router.DELEGATE("/files/:bucket/", http.MethodGet, http.NotFoundHandler())
+
+	assert.NotPanics(t, func() { router.POST("/api/:version/users/", http.NotFoundHandler()) })
+	assert.Panics(t, func() { router.PUT("/api/:other/users/", http.NotFoundHandler()) })
+	assert.NotPanics(t, func() { router.DELEGATE("/files/:bucket/", http.MethodPost, http.NotFoundHandler()) })
+	assert.Panics(t, func() { router.DELEGATE("/files/:dir/", http.MethodPut, http.NotFoundHandler()) })
+}
+
+func TestPathPrefix(t *testing.T) {
+	var _ http.Handler = NewRouter(&RouterOption{})
+
+	router := NewRouter(&RouterOption{})
+	router.GET("/users/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", router.PathPrefix("/v1/"))
+
+	testTable := []struct {
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{"/v1/users/", http.StatusOK, "/users/"},
+		{"/v1/users", http.StatusOK, "/users"},
+		{"/v1/posts/", http.StatusNotFound, string(errorNotFoundMessage)},
+		{"/users/", http.StatusNotFound, "404 page not found\n"},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	testTable := []struct {
+		Pattern string
+		Values  []string
+		Result  string
+		Err     error
+	}{
+		{"/", nil, "/", nil},
+		{"/users/", nil, "/users/", nil},
+		{"/users/:id/", []string{"12"}, "/users/12/", nil},
+		{"/:p1/:p2/p3/", []string{"a", "b"}, "/a/b/p3/", nil},
+		{"/users/:id/files/*/", []string{"12", "avatar"}, "/users/12/files/avatar/", nil},
+		{"/users/:id/", nil, "", ErrReverseValues},
+		{"/users/:id/", []string{"12", "13"}, "", ErrReverseValues},
+		{"/users/:id/", []string{"x?y=1"}, "/users/x%3Fy=1/", nil},
+		{"/users/:id/", []string{"john doe"}, "/users/john%20doe/", nil},
+		{"/users/:id/", []string{"a/b"}, "", ErrReverseValues},
+		{"/users/:id/", []string{""}, "", ErrReverseValues},
+	}
+	for _, test := range testTable {
+		result, err := router.Reverse(test.Pattern, test.Values...)
+		assert.ErrorIs(t, err, test.Err, test.Pattern)
+		assert.Equal(t, test.Result, result, test.Pattern)
+	}
+
+	router.GET("/users/:id/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target, _ := router.Reverse("/users/:id/", "x?y=1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDelegate(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	router.DELEGATE("/files/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("files"))
+	}))
+	router.DELEGATE("/files/private/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("private"))
+	}))
+	router.DELEGATE("/users/:id/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user"))
+	}))
+
+	testTable := []struct {
+		Method      string
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{http.MethodGet, "/files/", http.StatusOK, "files"},
+		{http.MethodGet, "/files", http.StatusOK, "files"},
+		{http.MethodGet, "/files/a/", http.StatusOK, "files"},
+		{http.MethodGet, "/files/css/a.css", http.StatusOK, "files"},
+		{http.MethodGet, "/files/private/", http.StatusOK, "private"},
+		{http.MethodGet, "/files/private/key/", http.StatusOK, "private"},
+		{http.MethodGet, "/users/12/", http.StatusOK, "user"},
+		{http.MethodGet, "/users/12/avatar/", http.StatusOK, "user"},
+		{http.MethodPost, "/files/a/", http.StatusMethodNotAllowed, string(errorMethodNotAllowedMessage)},
+		{http.MethodGet, "/users/", http.StatusNotFound, string(errorNotFoundMessage)},
+		{http.MethodGet, "/filesx/", http.StatusNotFound, string(errorNotFoundMessage)},
+		{http.MethodGet, "/", http.StatusNotFound, string(errorNotFoundMessage)},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(test.Method, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+	}
+}
+
+func TestMatchPrecedence(t *testing.T) {
+	testTable := []struct {
+		RequestPath string
+		Body        string
+	}{
+		{"/users/me/", "/users/me/"},
+		{"/users/12/", "/users/:id/"},
+		{"/users/me/profile/", "/users/me/:tab/"},
+		{"/users/12/profile/", "/users/:id/profile/"},
+		{"/users/12/posts/", "/:a/:b/:c/"},
+		{"/posts/12/profile/", "/:a/:b/profile/"},
+	}
+	// registered from least to most specific, repeated to rule out map ordering
+	for i := 0; i < 20; i++ {
+		router := NewRouter(&RouterOption{})
+		for _, path := range []string{"/:a/:b/:c/", "/:a/:b/profile/", "/users/:id/profile/", "/users/me/:tab/", "/users/:id/", "/users/me/"} {
+			path := path
+			router.GET(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(path))
+			}))
+		}
+		for _, test := range testTable {
+			req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+		}
+	}
+}
+
+func TestMatchFallback(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	for _, route := range []struct{ Method, Path string }{
+		{http.MethodGet, "/users/me/"},
+		{http.MethodGet, "/users/:id/"},
+		{http.MethodDelete, "/users/:id/"},
+		{http.MethodGet, "/users/me/:tab/"},
+		{http.MethodPost, "/users/:id/profile/"},
+		{http.MethodPut, "/users/:id/:tab/"},
+	} {
+		route := route
+		router.Register(route.Path, route.Method, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(route.Path))
+		}))
+	}
+	router.DELEGATE("/users/", http.MethodPatch, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("/users/*/"))
+	}))
+
+	testTable := []struct {
+		Method      string
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{http.MethodGet, "/users/me/", http.StatusOK, "/users/me/"},
+		{http.MethodGet, "/users/123/", http.StatusOK, "/users/:id/"},
+		{http.MethodDelete, "/users/me/", http.StatusOK, "/users/:id/"},
+		{http.MethodGet, "/users/me/profile/", http.StatusOK, "/users/me/:tab/"},
+		{http.MethodPost, "/users/me/profile/", http.StatusOK, "/users/:id/profile/"},
+		{http.MethodPut, "/users/me/profile/", http.StatusOK, "/users/:id/:tab/"},
+		{http.MethodPatch, "/users/me/profile/", http.StatusOK, "/users/*/"},
+		{http.MethodPost, "/users/me/", http.StatusMethodNotAllowed, string(errorMethodNotAllowedMessage)},
+		{http.MethodGet, "/posts/me/", http.StatusNotFound, string(errorNotFoundMessage)},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(test.Method, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, test.Method+" "+test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.Method+" "+test.RequestPath)
+	}
+}
+
+func TestStaticMethodFallsBackToParam(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	router.GET("/api/status/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("static"))
+	}))
+	router.POST("/api/:name/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("param"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/status/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "param", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/status/", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "static", w.Body.String())
+}
+
+func TestMethodsFor(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router.GET("/users/:id/", handler)
+	router.DELETE("/users/:id/", handler)
+	router.PUT("/users/me/", handler)
+	router.DELEGATE("/files/", http.MethodGet, handler)
+	router.Register("/files/", "KICK", handler)
+
+	testTable := []struct {
+		Path    string
+		Methods []string
+	}{
+		{"/users/12/", []string{"DELETE", "GET"}},
+		{"/users/12", []string{"DELETE", "GET"}},
+		{"/users/me/", []string{"DELETE", "GET", "PUT"}},
+		{"/files/", []string{"GET", "KICK"}},
+		{"/files/a/b/", []string{"GET"}},
+		{"/posts/", nil},
+	}
+	for _, test := range testTable {
+		assert.Equal(t, test.Methods, router.MethodsFor(test.Path), test.Path)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/users/12/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "DELETE, GET", w.Header().Get("Allow"))
+}
+
+func TestPreRouteHooks(t *testing.T) {
+	var order []string
+	router := NewRouter(&RouterOption{
+		PreRouteHooks: []func(*http.Request){
+			func(r *http.Request) {
+				order = append(order, "method")
+				if m := r.Header.Get("X-HTTP-Method-Override"); m != "" {
+					r.Method = m
+				}
+			},
+			func(r *http.Request) {
+				order = append(order, "path")
+				r.URL.Path = strings.ToLower(r.URL.Path)
+			},
+		},
+	})
+	router.POST("/users/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("post"))
+	}))
+	router.DELETE("/users/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("delete"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/USERS/", nil)
+	req.Header.Set("X-HTTP-Method-Override", http.MethodDelete)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "delete", w.Body.String())
+	assert.Equal(t, []string{"method", "path"}, order)
+}
+
+func TestCleanPath(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+	testTable := []struct {
+		Option      RouterOption
+		Method      string
+		RequestPath string
+		Status      int
+		Body        string
+		Location    string
+	}{
+		{RouterOption{}, http.MethodGet, "/api//users/", http.StatusNotFound, string(errorNotFoundMessage), ""},
+		{RouterOption{CleanPath: true}, http.MethodGet, "/api//users/", http.StatusOK, "/api/users/", ""},
+		{RouterOption{CleanPath: true}, http.MethodGet, "/api/./users", http.StatusOK, "/api/users", ""},
+		{RouterOption{CleanPath: true}, http.MethodGet, "/api/posts/../users/", http.StatusOK, "/api/users/", ""},
+		{RouterOption{CleanPath: true}, http.MethodGet, "/../api/users/", http.StatusOK, "/api/users/", ""},
+		{RouterOption{CleanPath: true, RedirectCleanPath: true}, http.MethodGet, "/api//users/?page=2", http.StatusMovedPermanently, "", "/api/users/?page=2"},
+		{RouterOption{CleanPath: true, RedirectCleanPath: true}, http.MethodPost, "/api//users/", http.StatusOK, "/api/users/", ""},
+		{RouterOption{CleanPath: true, RedirectCleanPath: true}, http.MethodGet, "/api/users/", http.StatusOK, "/api/users/", ""},
+	}
+	for testCase, test := range testTable {
+		option := test.Option
+		router := NewRouter(&option)
+		router.GET("/api/users/", handler)
+		router.POST("/api/users/", handler)
+
+		req := httptest.NewRequest(test.Method, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, testCase)
+		assert.Equal(t, test.Location, w.Header().Get("Location"), testCase)
+		if test.Status != http.StatusMovedPermanently {
+			assert.Equal(t, test.Body, w.Body.String(), testCase)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router.GET("/", handler)
+	router.GET("/users/", handler)
+	router.POST("/users/", handler)
+	router.GET("/users/:id/", handler)
+	router.DELETE("/users/:id/", handler)
+	router.PUT("/users/:id/posts/:post/", handler)
+	router.DELEGATE("/files/:bucket/", http.MethodGet, handler)
+	router.Health("/health/")
+
+	var visited []string
+	router.Walk(func(method, path string) {
+		visited = append(visited, method+" "+path)
+	})
+	assert.Equal(t, []string{
+		"GET /",
+		"GET /files/:bucket/*/",
+		"GET /health/",
+		"GET /users/",
+		"POST /users/",
+		"DELETE /users/:id/",
+		"GET /users/:id/",
+		"PUT /users/:id/posts/:post/",
+	}, visited)
+}
+
+func TestOptionalTrailingParam(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	router.GET("/download/:file?/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("download"))
+	}))
+	router.GET("/:page?/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("page"))
+	}))
+
+	testTable := []struct {
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{"/download/", http.StatusOK, "download"},
+		{"/download", http.StatusOK, "download"},
+		{"/download/report.pdf", http.StatusOK, "download"},
+		{"/download/report.pdf/x/", http.StatusNotFound, string(errorNotFoundMessage)},
+		{"/", http.StatusOK, "page"},
+		{"/about/", http.StatusOK, "page"},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+	}
+
+	var visited []string
+	router.Walk(func(method, path string) { visited = append(visited, path) })
+	assert.Equal(t, []string{"/", "/:page/", "/download/", "/download/:file/"}, visited)
+
+	for _, path := range []string{"/a/:b?/c/", "/a/b?/", "/a/:b?c/", "/a/:b?/*/", "/a/?/"} {
+		path := path
+		assert.Panics(t, func() { router.GET(path, http.NotFoundHandler()) }, path)
+	}
+}
+
+func TestNamedConstraints(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	for _, path := range []string{"/users/:name/", "/users/:id<uuid>/", "/posts/:id<int>/", "/tags/:tag<slug>/", "/files/:id<int>/"} {
+		path := path
+		router.GET(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(path))
+		}))
+	}
+	router.DELEGATE("/files/:id<int>/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("delegate"))
+	}))
+
+	testTable := []struct {
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{"/users/8c5e0a5e-3b1f-4f5e-9d5a-0c1e2f3a4b5c/", http.StatusOK, "/users/:id<uuid>/"},
+		{"/users/8c5e0a5e-3b1f-4f5e-9d5a/", http.StatusOK, "/users/:name/"},
+		{"/users/john/", http.StatusOK, "/users/:name/"},
+		{"/posts/12/", http.StatusOK, "/posts/:id<int>/"},
+		{"/posts/12a/", http.StatusNotFound, string(errorNotFoundMessage)},
+		{"/tags/go-router/", http.StatusOK, "/tags/:tag<slug>/"},
+		{"/tags/Go_Router/", http.StatusNotFound, string(errorNotFoundMessage)},
+		{"/files/12/a/", http.StatusOK, "delegate"},
+		{"/files/ab/a/", http.StatusNotFound, string(errorNotFoundMessage)},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+	}
+
+	var visited []string
+	router.Walk(func(method, path string) { visited = append(visited, path) })
+	assert.Contains(t, visited, "/users/:id<uuid>/")
+	assert.Contains(t, visited, "/files/:id<int>/*/")
+
+	assert.Panics(t, func() { router.GET("/orders/:id<date>/", http.NotFoundHandler()) })
+}
+
+func TestLiteralStarSegments(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	for _, path := range []string{"/:x/*b/", "/:x/*ab/"} {
+		path := path
+		router.GET(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(path))
+		}))
+	}
+
+	testTable := []struct {
+		RequestPath string
+		Status      int
+		Body        string
+	}{
+		{"/a/*b/", http.StatusOK, "/:x/*b/"},
+		{"/a/*ab/", http.StatusOK, "/:x/*ab/"},
+		{"/a/b/", http.StatusNotFound, string(errorNotFoundMessage)},
+		{"/a/ab/", http.StatusNotFound, string(errorNotFoundMessage)},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		assert.NotPanics(t, func() { router.ServeHTTP(w, req) }, test.RequestPath)
+		assert.Equal(t, test.Status, w.Code, test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+	}
+	assert.Equal(t, 0, segmentRank("*ab"))
+	assert.Equal(t, 1, segmentRank("*<int>"))
+	assert.Equal(t, 2, segmentRank("*"))
+}
+
+func TestDelegateConflicts(t *testing.T) {
+	logger := &fakeLogger{}
+	router := NewRouter(&RouterOption{Logf: logger})
+	handler := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		})
+	}
+	router.GET("/files/special/", handler("special"))
+	router.GET("/files/archive/", handler("archive"))
+	router.GET("/files/:name/", handler("name"))
+	router.GET("/:dir/readme/", handler("readme"))
+	router.DELEGATE("/files/", http.MethodGet, handler("delegate"))
+	router.GET("/files/other/", handler("other"))
+	router.GET("/files/:name/:version<int>/", handler("version"))
+	router.GET("/users/", handler("users"))
+	router.GET("/users/:id/", handler("user"))
+
+	assert.Equal(t, []string{
+		"WARN route /:dir/readme/ overlaps delegate /files/*/, the route takes precedence",
+		"WARN route /files/:name/ overlaps delegate /files/*/, the route takes precedence",
+		"WARN route /files/archive/ overlaps delegate /files/*/, the route takes precedence",
+		"WARN route /files/special/ overlaps delegate /files/*/, the route takes precedence",
+		"WARN route /files/other/ overlaps delegate /files/*/, the route takes precedence",
+		"WARN route /files/:name/:version<int>/ overlaps delegate /files/*/, the route takes precedence",
+	}, logger.lines)
+
+	testTable := []struct {
+		RequestPath string
+		Body        string
+	}{
+		{"/files/special/", "special"},
+		{"/files/special/x/", "delegate"},
+		{"/files/other/", "other"},
+		{"/files/other/x/", "delegate"},
+		{"/files/", "delegate"},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(http.MethodGet, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Body, w.Body.String(), test.RequestPath)
+	}
+}
+
+func TestDelegateRegisteredBeforeParamRoute(t *testing.T) {
+	router := NewRouter(&RouterOption{})
+	handler := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		})
+	}
+	router.DELEGATE("/files/:bucket/", http.MethodGet, handler("delegate"))
+	router.GET("/files/:name/", handler("get"))
+	router.POST("/files/:name/", handler("post"))
+
+	testTable := []struct {
+		Method      string
+		RequestPath string
+		Body        string
+	}{
+		{http.MethodGet, "/files/x/", "get"},
+		{http.MethodPost, "/files/x/", "post"},
+		{http.MethodGet, "/files/x/y/", "delegate"},
+	}
+	for _, test := range testTable {
+		req := httptest.NewRequest(test.Method, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, test.Method+" "+test.RequestPath)
+		assert.Equal(t, test.Body, w.Body.String(), test.Method+" "+test.RequestPath)
+	}
+
+	var visited []string
+	router.Walk(func(method, path string) { visited = append(visited, method+" "+path) })
+	assert.Equal(t, []string{"GET /files/:bucket/*/", "GET /files/:name/", "POST /files/:name/"}, visited)
+}
+
+func TestDelegatePrecedence(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		router := NewRouter(&RouterOption{})
+		router.DELEGATE("/files/:bucket/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("bucket"))
+		}))
+		router.DELEGATE("/files/public/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("public"))
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/files/public/a/", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, "public", w.Body.String())
+	}
+}
+
+func TestRouterOptionHandlers(t *testing.T) {
+	teapot := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	gone := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	testTable := []struct {
+		Option      *RouterOption
+		Method      string
+		RequestPath string
+		Status      int
+	}{
+		{nil, http.MethodPost, "/coffee/", http.StatusMethodNotAllowed},
+		{nil, http.MethodGet, "/tea/", http.StatusNotFound},
+		{&RouterOption{}, http.MethodPost, "/coffee/", http.StatusMethodNotAllowed},
+		{&RouterOption{}, http.MethodGet, "/tea/", http.StatusNotFound},
+		{&RouterOption{MethodNotAllowed: teapot}, http.MethodPost, "/coffee/", http.StatusTeapot},
+		{&RouterOption{MethodNotAllowed: teapot}, http.MethodGet, "/tea/", http.StatusNotFound},
+		{&RouterOption{NotFoundHandler: gone}, http.MethodPost, "/coffee/", http.StatusMethodNotAllowed},
+		{&RouterOption{NotFoundHandler: gone}, http.MethodGet, "/tea/", http.StatusGone},
+	}
+	for testCase, test := range testTable {
+		router := NewRouter(test.Option)
+		router.GET("/coffee/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		req := httptest.NewRequest(test.Method, test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, testCase)
+	}
+}
+
+func TestUnregisteredMethod(t *testing.T) {
+	teapot := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	testTable := []struct {
+		Option      *RouterOption
+		RequestPath string
+		Status      int
+		Allow       string
+	}{
+		{nil, "/coffee/", http.StatusMethodNotAllowed, "GET, POST"},
+		{nil, "/coffee/12/", http.StatusMethodNotAllowed, "DELETE"},
+		{nil, "/tea/", http.StatusNotFound, ""},
+		{&RouterOption{MethodNotAllowed: teapot}, "/coffee/", http.StatusTeapot, "GET, POST"},
+		{&RouterOption{MethodNotAllowed: teapot}, "/tea/", http.StatusNotFound, ""},
+	}
+	for testCase, test := range testTable {
+		router := NewRouter(test.Option)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		router.GET("/coffee/", handler)
+		router.POST("/coffee/", handler)
+		router.DELETE("/coffee/:id/", handler)
+
+		req := httptest.NewRequest("BREW", test.RequestPath, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, test.Status, w.Code, testCase)
+		assert.Equal(t, test.Allow, w.Header().Get("Allow"), testCase)
+	}
+}
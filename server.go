@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrServerStarted is returned by ListenAndServe while the router is already serving.
+var ErrServerStarted = errors.New("router server already started")
+
+// ListenAndServe serves the router on addr until Shutdown is called.
+// Once Shutdown has been called it returns http.ErrServerClosed without serving.
+func (rt *router) ListenAndServe(addr string) error {
+	rt.serverMu.Lock()
+	if rt.serverClosed {
+		rt.serverMu.Unlock()
+		return http.ErrServerClosed
+	}
+	if rt.server != nil {
+		rt.serverMu.Unlock()
+		return ErrServerStarted
+	}
+	server := &http.Server{Addr: addr, Handler: rt}
+	rt.server = server
+	rt.serverMu.Unlock()
+
+	err := server.ListenAndServe()
+	rt.serverMu.Lock()
+	rt.server = nil
+	rt.serverMu.Unlock()
+	return err
+}
+
+// Shutdown stops the server started by ListenAndServe, or keeps a later one from starting,
+// and waits until every in-flight request has been served or ctx is done.
+// Requests reaching the router through another server, e.g. http.ListenAndServe or
+// PathPrefix, are waited for as well but that server is not stopped.
+func (rt *router) Shutdown(ctx context.Context) error {
+	rt.serverMu.Lock()
+	rt.serverClosed = true
+	server := rt.server
+	rt.serverMu.Unlock()
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+
+	rt.inFlightMu.Lock()
+	busy, idle := rt.inFlight > 0, rt.idle
+	rt.inFlightMu.Unlock()
+	if !busy {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// beginRequest counts a request served by the router.
+func (rt *router) beginRequest() {
+	rt.inFlightMu.Lock()
+	if rt.inFlight == 0 {
+		rt.idle = make(chan struct{})
+	}
+	rt.inFlight++
+	rt.inFlightMu.Unlock()
+}
+
+// endRequest uncounts a served request and wakes Shutdown once none are left.
+func (rt *router) endRequest() {
+	rt.inFlightMu.Lock()
+	rt.inFlight--
+	if rt.inFlight == 0 {
+		close(rt.idle)
+	}
+	rt.inFlightMu.Unlock()
+}
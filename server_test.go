@@ -0,0 +1,173 @@
+package router
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	started := make(chan struct{})
+	finished := make(chan time.Time, 1)
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/slow/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+		finished <- time.Now()
+	}))
+
+	served := make(chan error, 1)
+	go func() { served <- rt.ListenAndServe(addr) }()
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		res, err := http.Get("http://" + addr + "/slow/")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		responses <- result{string(body), err}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, rt.Shutdown(ctx))
+	shutdownAt := time.Now()
+
+	res := <-responses
+	assert.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+	assert.False(t, (<-finished).After(shutdownAt), "handler finished after Shutdown returned")
+	assert.Equal(t, http.ErrServerClosed, <-served)
+}
+
+func TestShutdownWithoutServer(t *testing.T) {
+	assert.NoError(t, NewRouter(&RouterOption{}).Shutdown(context.Background()))
+}
+
+func TestShutdownDuringConcurrentRequests(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/ping/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+	}))
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping/", nil))
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		err := rt.Shutdown(ctx)
+		cancel()
+		if err != nil {
+			assert.Equal(t, context.DeadlineExceeded, err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	assert.NoError(t, rt.Shutdown(context.Background()))
+}
+
+func TestShutdownTimesOutOnStuckRequest(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	rt := NewRouter(&RouterOption{})
+	rt.GET("/stuck/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	served := make(chan struct{})
+	go func() {
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stuck/", nil))
+		close(served)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, rt.Shutdown(ctx))
+	close(release)
+	<-served
+	assert.NoError(t, rt.Shutdown(context.Background()))
+}
+
+func TestListenAndServeAfterShutdown(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	assert.NoError(t, rt.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, rt.ListenAndServe("127.0.0.1:0"))
+}
+
+func TestListenAndServeTwice(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	served := make(chan error, 1)
+	go func() { served <- rt.ListenAndServe("127.0.0.1:0") }()
+	for i := 0; i < 100; i++ {
+		r := rt.(*router)
+		r.serverMu.Lock()
+		started := r.server != nil
+		r.serverMu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	assert.Equal(t, ErrServerStarted, rt.ListenAndServe("127.0.0.1:0"))
+	assert.NoError(t, rt.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-served)
+}
+
+func TestShutdownRightAfterListenAndServe(t *testing.T) {
+	rt := NewRouter(&RouterOption{})
+	served := make(chan error, 1)
+	go func() { served <- rt.ListenAndServe("127.0.0.1:0") }()
+	assert.NoError(t, rt.Shutdown(context.Background()))
+
+	select {
+	case err := <-served:
+		assert.Equal(t, http.ErrServerClosed, err)
+	case <-time.After(time.Second):
+		t.Error("ListenAndServe still serving after Shutdown")
+	}
+}